@@ -0,0 +1,156 @@
+package watch
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/gopsql/logger"
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectConfig describes one project entry in a config file loaded with
+// WithConfigFile. Fields left empty fall back to the same defaults as the
+// builder methods of watch.
+type ProjectConfig struct {
+	Name string            `yaml:"name" json:"name"`
+	Path string            `yaml:"path" json:"path"`
+	Env  map[string]string `yaml:"env" json:"env"`
+	Args []string          `yaml:"args" json:"args"`
+
+	Output    string   `yaml:"output" json:"output"`
+	NoRun     bool     `yaml:"no_run" json:"no_run"`
+	Test      bool     `yaml:"test" json:"test"`
+	Clean     bool     `yaml:"clean" json:"clean"`
+	BuildArgs []string `yaml:"build_args" json:"build_args"`
+
+	Watch struct {
+		Exts   []string `yaml:"exts" json:"exts"`
+		Paths  []string `yaml:"paths" json:"paths"`
+		Ignore []string `yaml:"ignore" json:"ignore"`
+	} `yaml:"watch" json:"watch"`
+}
+
+// Config is the root of a config file loaded with WithConfigFile, listing
+// independent projects to watch concurrently.
+type Config struct {
+	Projects []ProjectConfig `yaml:"projects" json:"projects"`
+}
+
+// WithConfigFile sets a YAML or JSON config file (format picked by file
+// extension, YAML by default) describing one or more independent projects
+// to watch concurrently. When set, Do fans out one goroutine per project
+// instead of watching the single directory/output configured on w, and the
+// builder methods that configure a single project (InDirectory, WithOutput,
+// etc.) are ignored in favor of the per-project settings in the file.
+func (w *watch) WithConfigFile(path string) *watch {
+	w.configFile = path
+	return w
+}
+
+func loadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var config Config
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, &config)
+	default:
+		err = yaml.Unmarshal(data, &config)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("watch: parsing config file %q: %w", path, err)
+	}
+	return &config, nil
+}
+
+// doConfig runs every project in w.configFile concurrently, each in its own
+// goroutine with its own runner pair and watcher.Watcher. A project's error
+// is logged as soon as it occurs, through its own logger, since the other
+// projects' watchers normally run forever and doConfig would otherwise sit
+// waiting on them with the failure unreported. It returns the first error
+// any project produces once they've all stopped.
+func (w *watch) doConfig() error {
+	config, err := loadConfig(w.configFile)
+	if err != nil {
+		return err
+	}
+	if len(config.Projects) == 0 {
+		return fmt.Errorf("watch: config file %q has no projects", w.configFile)
+	}
+
+	w.projects = make([]*watch, len(config.Projects))
+	for i, p := range config.Projects {
+		w.projects[i] = watchFromProject(p, i, w)
+	}
+
+	errs := make(chan error, len(w.projects))
+	var wg sync.WaitGroup
+	for _, pw := range w.projects {
+		wg.Add(1)
+		go func(pw *watch) {
+			defer wg.Done()
+			err := pw.Do()
+			if err != nil {
+				pw.logInfo(logger.CyanString("Error:"), err.Error())
+			}
+			errs <- err
+		}(pw)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// watchFromProject builds the *watch for a single project entry, inheriting
+// the logger and go path from the parent so every project logs through the
+// same logger, prefixed with its own name to keep output distinguishable.
+func watchFromProject(p ProjectConfig, index int, parent *watch) *watch {
+	name := p.Name
+	if name == "" {
+		if p.Path != "" && p.Path != "." {
+			name = filepath.Base(p.Path)
+		} else {
+			name = fmt.Sprintf("project-%d", index+1)
+		}
+	}
+
+	pw := NewWatch().
+		InDirectory(p.Path).
+		SetNoRun(p.NoRun).
+		SetTest(p.Test).
+		SetClean(p.Clean).
+		WithAppRunArgs(p.Args...).
+		WithGoBuildArgs(p.BuildArgs...).
+		WithGoPath(parent.goPath).
+		WithLogger(parent.logger).
+		WithEnv(p.Env)
+	pw.name = name
+	pw.subProject = true
+
+	if len(p.Watch.Exts) > 0 {
+		pw.WithFileExts(p.Watch.Exts...)
+	}
+	if p.Output != "" {
+		pw.WithOutput(p.Output)
+	}
+	if len(p.Watch.Ignore) > 0 {
+		pw.IgnorePaths(p.Watch.Ignore...)
+	}
+	if len(p.Watch.Paths) > 0 {
+		pw.WithWatchPaths(p.Watch.Paths...)
+	}
+
+	return pw
+}
@@ -31,9 +31,14 @@ func main() {
 	var prebuildStr string
 	var changeDir string
 	var rebuildKeyStr string
+	var configFile string
+	var target string
 	ignore := list{"node_modules", ".git", "dist"}
+	ignorePaths := list{}
 	exts := list{".go", ".mod"}
+	env := list{}
 
+	flag.StringVar(&configFile, "config", "", "path to a YAML or JSON config file listing projects to watch concurrently; short-circuits all other flags except -go")
 	flag.StringVar(&goPath, "go", "", "path to the go executable")
 	flag.BoolVar(&noRun, "no-run", false, "do not run the executable after go build")
 	flag.BoolVar(&isTest, "test", false, "run go test instead of go build")
@@ -42,7 +47,10 @@ func main() {
 	flag.StringVar(&changeDir, "cd", "", "set working directory of commands")
 	flag.StringVar(&rebuildKeyStr, "rebuild-key", "r", "key to rebuild")
 	flag.Var(&ignore, "ignore", "add extra directory name to ignore")
+	flag.Var(&ignorePaths, "ignore-path", "add glob pattern (relative to watched directory, \"**\" matches any depth) to ignore, repeatable")
 	flag.Var(&exts, "ext", "add extra file extensions to watch")
+	flag.Var(&env, "env", "add environment variable KEY=VAL to build, prebuild and app run commands (repeatable)")
+	flag.StringVar(&target, "target", "", "cross-compile target GOOS/GOARCH, e.g. linux/arm64")
 	flag.Usage = func() {
 		o := flag.CommandLine.Output()
 		fmt.Fprintln(o, "Usage:", os.Args[0], "[options] -- [go build/test args] -- [app run args]")
@@ -52,6 +60,15 @@ func main() {
 	}
 	flag.Parse()
 
+	if configFile != "" {
+		watch.NewWatch().
+			WithConfigFile(configFile).
+			WithGoPath(goPath).
+			WithLogger(logger.StandardLogger).
+			MustDo()
+		return
+	}
+
 	var rebuildKey byte
 	if rebuildKeyStr != "" {
 		rebuildKey = rebuildKeyStr[0]
@@ -92,8 +109,27 @@ func main() {
 		}
 	}
 
+	envMap := map[string]string{}
+	for _, kv := range env {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 {
+			log.Fatalln("invalid -env value, expected KEY=VAL:", kv)
+		}
+		envMap[parts[0]] = parts[1]
+	}
+
+	var targetGOOS, targetGOARCH string
+	if target != "" {
+		parts := strings.SplitN(target, "/", 2)
+		targetGOOS = parts[0]
+		if len(parts) == 2 {
+			targetGOARCH = parts[1]
+		}
+	}
+
 	watch.NewWatch().
 		IgnoreDirectory(ignore...).
+		IgnorePaths(ignorePaths...).
 		SetNoRun(noRun).
 		SetTest(isTest).
 		SetClean(goClean).
@@ -106,5 +142,8 @@ func main() {
 		WithLogger(logger.StandardLogger).
 		WithRebuildKey(rebuildKey).
 		WithFileExts(exts...).
+		WithEnv(envMap).
+		WithBuildEnv(envMap).
+		WithTarget(targetGOOS, targetGOARCH).
 		MustDo()
 }
@@ -0,0 +1,141 @@
+package watch
+
+import (
+	"bytes"
+	"os"
+
+	"github.com/gopsql/logger"
+)
+
+// Command is a single shell command run as part of a hook pipeline (see
+// BeforeBuild, AfterBuild, BeforeRun, AfterRun and OnChange). Dir defaults to
+// the watch's working directory when empty. Hooks are set only through the
+// builder methods: ProjectConfig has no hook fields, so a config file loaded
+// with WithConfigFile sets none.
+type Command struct {
+	Cmd             []string // command and its arguments, e.g. []string{"go", "generate", "./..."}
+	Dir             string   // working directory, defaults to the watch's working directory
+	ContinueOnError bool     // true to keep running the rest of the cycle if this command fails
+	CaptureOutput   bool     // true to capture output instead of piping it straight to stdout
+}
+
+// BeforeBuild registers a shell command to run before go build or go test.
+// Call it multiple times to build a pipeline; commands run in the order
+// registered. Use BeforeBuildCommand instead to set a working dir,
+// ContinueOnError or CaptureOutput on the hook.
+func (w *watch) BeforeBuild(cmd ...string) *watch {
+	w.beforeBuild = append(w.beforeBuild, Command{Cmd: cmd})
+	return w
+}
+
+// BeforeBuildCommand registers a hook Command to run before go build or go
+// test, with full control over its Dir, ContinueOnError and CaptureOutput.
+func (w *watch) BeforeBuildCommand(c Command) *watch {
+	w.beforeBuild = append(w.beforeBuild, c)
+	return w
+}
+
+// AfterBuild registers a shell command to run after a successful go build or
+// go test, before the app (or next hook) runs. Use AfterBuildCommand instead
+// to set a working dir, ContinueOnError or CaptureOutput on the hook.
+func (w *watch) AfterBuild(cmd ...string) *watch {
+	w.afterBuild = append(w.afterBuild, Command{Cmd: cmd})
+	return w
+}
+
+// AfterBuildCommand registers a hook Command to run after a successful go
+// build or go test, with full control over its Dir, ContinueOnError and
+// CaptureOutput.
+func (w *watch) AfterBuildCommand(c Command) *watch {
+	w.afterBuild = append(w.afterBuild, c)
+	return w
+}
+
+// BeforeRun registers a shell command to run before the built app starts.
+// If a BeforeRun hook whose ContinueOnError is false fails, the app is not
+// started. Use BeforeRunCommand
+// instead to set a working dir, ContinueOnError or CaptureOutput on the
+// hook.
+func (w *watch) BeforeRun(cmd ...string) *watch {
+	w.beforeRun = append(w.beforeRun, Command{Cmd: cmd})
+	return w
+}
+
+// BeforeRunCommand registers a hook Command to run before the built app
+// starts, with full control over its Dir, ContinueOnError and
+// CaptureOutput.
+func (w *watch) BeforeRunCommand(c Command) *watch {
+	w.beforeRun = append(w.beforeRun, c)
+	return w
+}
+
+// AfterRun registers a shell command to run right after the built app has
+// been started. Use AfterRunCommand instead to set a working dir,
+// ContinueOnError or CaptureOutput on the hook.
+func (w *watch) AfterRun(cmd ...string) *watch {
+	w.afterRun = append(w.afterRun, Command{Cmd: cmd})
+	return w
+}
+
+// AfterRunCommand registers a hook Command to run right after the built app
+// has been started, with full control over its Dir, ContinueOnError and
+// CaptureOutput.
+func (w *watch) AfterRunCommand(c Command) *watch {
+	w.afterRun = append(w.afterRun, c)
+	return w
+}
+
+// OnChange registers a shell command to run as soon as a watched file
+// change is detected, before the mod tidy / build / run cycle begins. Use
+// OnChangeCommand instead to set a working dir, ContinueOnError or
+// CaptureOutput on the hook.
+func (w *watch) OnChange(cmd ...string) *watch {
+	w.onChange = append(w.onChange, Command{Cmd: cmd})
+	return w
+}
+
+// OnChangeCommand registers a hook Command to run as soon as a watched file
+// change is detected, with full control over its Dir, ContinueOnError and
+// CaptureOutput.
+func (w *watch) OnChangeCommand(c Command) *watch {
+	w.onChange = append(w.onChange, c)
+	return w
+}
+
+// runHooks runs each Command in hooks in order. Output is piped straight to
+// stdout, unless CaptureOutput is set, in which case it's buffered and,
+// only if the command fails, written to stdout after a header logged
+// through the logger; the output itself does not go through the logger. It
+// stops and returns the
+// first error from a command whose ContinueOnError is false.
+func (w *watch) runHooks(label string, hooks []Command) error {
+	for _, c := range hooks {
+		if len(c.Cmd) == 0 {
+			continue
+		}
+		r := newRunner(c.Cmd[0], c.Cmd[1:]...)
+		dir := c.Dir
+		if dir == "" {
+			dir = w.workingDir
+		}
+		r.SetDir(dir)
+
+		var buf bytes.Buffer
+		if c.CaptureOutput {
+			r.SetWriter(&buf)
+		} else {
+			r.SetWriter(os.Stdout)
+		}
+
+		w.logInfo(logger.CyanString(label+":"), r.String())
+		err := r.Run(true)
+		if err != nil && c.CaptureOutput && buf.Len() > 0 {
+			w.logInfo(logger.CyanString(label + " output:"))
+			os.Stdout.Write(buf.Bytes())
+		}
+		if err != nil && !c.ContinueOnError {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package watch
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Ignore holds the file-ignore rules for a watch: glob patterns, extensions
+// and whether hidden files are included. See IgnorePaths, IgnoreExts and
+// IncludeHidden.
+type Ignore struct {
+	paths         []string // glob patterns, matched against the path relative to the watched root
+	exts          []string // file extensions / suffixes to ignore
+	includeHidden bool     // true to watch dotfiles and dot-directories, skipped by default
+}
+
+// IgnorePaths adds glob patterns to ignore, evaluated against each file's
+// path relative to the watched root. Patterns use path/filepath.Match
+// syntax, plus "**" to match any number of path segments, e.g.
+// "**/testdata/**" or "cmd/*/gen_*.go".
+func (w *watch) IgnorePaths(globs ...string) *watch {
+	w.ignore.paths = append(w.ignore.paths, globs...)
+	return w
+}
+
+// IgnoreExts adds file extensions or suffixes to ignore, regardless of
+// WithFileExts.
+func (w *watch) IgnoreExts(exts ...string) *watch {
+	w.ignore.exts = append(w.ignore.exts, exts...)
+	return w
+}
+
+// IncludeHidden sets whether dotfiles and directories whose name starts with
+// "." are watched. They're ignored by default.
+func (w *watch) IncludeHidden(include bool) *watch {
+	w.ignore.includeHidden = include
+	return w
+}
+
+// IgnoreDirectory adds directory name to directory ignore list. Ignore
+// directories without go files could reduce CPU usage. It's a thin wrapper
+// around IgnorePaths that matches the directory by name at any depth.
+func (w *watch) IgnoreDirectory(dirs ...string) *watch {
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		w.IgnorePaths(filepath.Join("**", dir), filepath.Join("**", dir, "**"))
+	}
+	return w
+}
+
+// shouldIgnore reports whether fullPath, relative to root, matches any
+// ignore rule: a hidden dotfile/dot-directory (unless IncludeHidden), an
+// ignored extension, or an ignored glob.
+func (w *watch) shouldIgnore(root, fullPath string) bool {
+	rel, err := filepath.Rel(root, fullPath)
+	if err != nil {
+		rel = fullPath
+	}
+	rel = filepath.ToSlash(rel)
+
+	if !w.ignore.includeHidden && isHidden(rel) {
+		return true
+	}
+	for _, ext := range w.ignore.exts {
+		if strings.HasSuffix(fullPath, ext) {
+			return true
+		}
+	}
+	for _, glob := range w.ignore.paths {
+		if globMatch(glob, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+func isHidden(rel string) bool {
+	for _, part := range strings.Split(rel, "/") {
+		if part != "" && part != "." && part != ".." && strings.HasPrefix(part, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// globMatch reports whether name matches pattern, where pattern is a
+// path/filepath.Match pattern whose segments are separated by "/", with the
+// extra doublestar-style segment "**" matching zero or more path segments.
+func globMatch(pattern, name string) bool {
+	return globMatchParts(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func globMatchParts(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	if pattern[0] == "**" {
+		if globMatchParts(pattern[1:], name) {
+			return true
+		}
+		if len(name) == 0 {
+			return false
+		}
+		return globMatchParts(pattern, name[1:])
+	}
+	if len(name) == 0 {
+		return false
+	}
+	if ok, _ := filepath.Match(pattern[0], name[0]); !ok {
+		return false
+	}
+	return globMatchParts(pattern[1:], name[1:])
+}
@@ -0,0 +1,137 @@
+package watch
+
+import (
+	"bufio"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/gopsql/logger"
+	"github.com/radovskyb/watcher"
+)
+
+// WithCommands registers custom REPL commands on top of the built-in ones
+// (r, t, c, s, x, q and !<shell command>). Each function is called with w
+// when its key is entered on stdin.
+func (w *watch) WithCommands(cmds map[string]func(*watch) error) *watch {
+	if w.commands == nil {
+		w.commands = map[string]func(*watch) error{}
+	}
+	for key, fn := range cmds {
+		w.commands[key] = fn
+	}
+	return w
+}
+
+// startCommandDispatcher reads lines from stdin and dispatches them to the
+// built-in commands (rebuild, test, clean, stop, start, quit, ad-hoc shell
+// command) or to a custom command registered with WithCommands.
+func (w *watch) startCommandDispatcher(wa *watcher.Watcher, app, clean *runner, goPath string, buildEnv map[string]string) {
+	builtin := map[string]func() error{
+		"r": func() error {
+			wa.TriggerEvent(watcher.Create, nil)
+			return nil
+		},
+		"t": func() error {
+			w.logInfo(logger.CyanString("Testing..."))
+			test := newRunner(goPath, append([]string{"test"}, w.goBuildArgs...)...)
+			test.SetDir(w.workingDir)
+			test.SetWriter(os.Stdout)
+			test.SetEnv(buildEnv)
+			return test.Run(true)
+		},
+		"c": func() error {
+			w.logInfo(logger.CyanString("Cleaning..."))
+			return clean.Run(true)
+		},
+		"s": func() error {
+			w.logInfo(logger.CyanString("Stopping app..."))
+			return app.Kill()
+		},
+		"x": func() error {
+			w.logInfo(logger.CyanString("Starting app..."))
+			return app.Run(false)
+		},
+		"q": func() error {
+			w.logInfo(logger.CyanString("Quitting..."))
+			app.Kill()
+			wa.Close()
+			return nil
+		},
+	}
+
+	w.printCommandHelp()
+
+	go func() {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			switch {
+			case line == "":
+				continue
+			case strings.HasPrefix(line, "!"):
+				w.runShellCommand(strings.TrimPrefix(line, "!"))
+			case w.rebuildKey > 0 && line == string(w.rebuildKey):
+				wa.TriggerEvent(watcher.Create, nil)
+			default:
+				fn, ok := builtin[line]
+				if !ok {
+					var custom func(*watch) error
+					custom, ok = w.commands[line]
+					if ok {
+						fn = func() error { return custom(w) }
+					}
+				}
+				if !ok {
+					w.logInfo(logger.CyanString("Unknown command:"), line)
+					continue
+				}
+				if err := fn(); err != nil {
+					w.logInfo(logger.CyanString("Error:"), err.Error())
+				}
+				if line == "q" {
+					return
+				}
+			}
+		}
+	}()
+}
+
+// runShellCommand runs cmd through the system shell for the "!<shell cmd>"
+// REPL command. The command line is logged through the logger; its output
+// goes straight to stdout, not through the logger.
+func (w *watch) runShellCommand(cmd string) {
+	if strings.TrimSpace(cmd) == "" {
+		return
+	}
+	shell, shellArgs := "sh", []string{"-c", cmd}
+	if runtime.GOOS == "windows" {
+		shell, shellArgs = "cmd", []string{"/C", cmd}
+	}
+	r := newRunner(shell, shellArgs...)
+	r.SetDir(w.workingDir)
+	r.SetWriter(os.Stdout)
+	w.logInfo(logger.CyanString("Running:"), cmd)
+	if err := r.Run(true); err != nil {
+		w.logInfo(logger.CyanString("Error:"), err.Error())
+	}
+}
+
+// printCommandHelp prints a short banner listing the REPL key bindings.
+func (w *watch) printCommandHelp() {
+	if w.logger == nil {
+		return
+	}
+	w.logInfo(logger.CyanString("Commands:"),
+		"r rebuild, t test, c clean, s stop app, x start app, q quit, !<cmd> run shell command")
+	if len(w.commands) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(w.commands))
+	for key := range w.commands {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	w.logInfo(logger.CyanString("Custom commands:"), strings.Join(keys, ", "))
+}
@@ -9,6 +9,7 @@ import (
 	"os"
 	"os/exec"
 	"runtime"
+	"strings"
 	"time"
 )
 
@@ -16,6 +17,7 @@ type runner struct {
 	dir       string
 	bin       string
 	args      []string
+	env       []string // extra KEY=VAL entries merged onto os.Environ(), see SetEnv
 	writer    io.Writer
 	command   *exec.Cmd
 	starttime time.Time
@@ -47,6 +49,12 @@ func (r *runner) Run(wait bool) error {
 	return nil
 }
 
+// String returns the command and its arguments joined by spaces, for
+// logging.
+func (r *runner) String() string {
+	return strings.Join(append([]string{r.bin}, r.args...), " ")
+}
+
 func (r *runner) Info() (os.FileInfo, error) {
 	return os.Stat(r.bin)
 }
@@ -59,6 +67,21 @@ func (r *runner) SetWriter(writer io.Writer) {
 	r.writer = writer
 }
 
+// SetEnv sets extra environment variables for the command, merged onto
+// os.Environ(). An empty or nil env makes the command inherit the parent
+// process's environment unchanged.
+func (r *runner) SetEnv(env map[string]string) {
+	if len(env) == 0 {
+		r.env = nil
+		return
+	}
+	merged := os.Environ()
+	for k, v := range env {
+		merged = append(merged, k+"="+v)
+	}
+	r.env = merged
+}
+
 func (r *runner) Kill() error {
 	if r.command != nil && r.command.Process != nil {
 		done := make(chan error)
@@ -95,6 +118,7 @@ func (r *runner) Exited() bool {
 func (r *runner) runBin(wait bool) error {
 	r.command = exec.Command(r.bin, r.args...)
 	r.command.Dir = r.dir
+	r.command.Env = r.env
 
 	stdout, err := r.command.StdoutPipe()
 	if err != nil {
@@ -1,7 +1,6 @@
 package watch
 
 import (
-	"bufio"
 	"fmt"
 	"os"
 	"os/exec"
@@ -28,23 +27,40 @@ var (
 )
 
 type watch struct {
-	appRunArgs  []string      // extra arguemnts to run the app
-	goPath      string        // defaults to "go"
-	goBuildArgs []string      // extra arguments to go build or go test
-	noRun       bool          // true to not run executable after go build
-	isTest      bool          // true to run go test instead of go build
-	prebuild    []string      // extra command to run before go build or go test
-	cleanFirst  bool          // run go clean command before go build or go test
-	logger      logger.Logger // no logger by default
-	extsToWatch []string      // file extensions / suffix to watch
-	ignoreDirs  []string      // list of directories not to watch
-	rebuildKey  byte          // key to enter to run go build or go test again
+	appRunArgs  []string                      // extra arguemnts to run the app
+	goPath      string                        // defaults to "go"
+	goBuildArgs []string                      // extra arguments to go build or go test
+	noRun       bool                          // true to not run executable after go build
+	isTest      bool                          // true to run go test instead of go build
+	prebuild    []string                      // extra command to run before go build or go test
+	cleanFirst  bool                          // run go clean command before go build or go test
+	logger      logger.Logger                 // no logger by default
+	extsToWatch []string                      // file extensions / suffix to watch
+	extraPaths  []string                      // additional directories to watch recursively, see WithWatchPaths
+	ignore      Ignore                        // file-ignore rules, see IgnorePaths, IgnoreExts and IncludeHidden
+	rebuildKey  byte                          // deprecated alias for the "r" REPL command, see WithRebuildKey
+	commands    map[string]func(*watch) error // custom REPL commands, see WithCommands
 
 	workingDir string // working directory for commands
 	directory  string // directory to watch
 	output     string // path to output file
 
 	lastPrebuildAt *time.Time // skip next run first
+
+	configFile   string            // path to config file, see WithConfigFile
+	projects     []*watch          // independent projects when using a config file
+	name         string            // project name, prefixes log output when run as part of a config file
+	subProject   bool              // true when this watch is one project of a config file's fan-out; disables the stdin REPL
+	env          map[string]string // extra environment variables for prebuild and app run commands, see WithEnv
+	buildEnv     map[string]string // extra environment variables for the go build/test command, see WithBuildEnv
+	targetGOOS   string            // GOOS to cross-compile for, see WithTarget
+	targetGOARCH string            // GOARCH to cross-compile for, see WithTarget
+
+	beforeBuild []Command // hooks run before go build or go test, see BeforeBuild
+	afterBuild  []Command // hooks run after a successful go build or go test, see AfterBuild
+	beforeRun   []Command // hooks run before the app starts, see BeforeRun
+	afterRun    []Command // hooks run after the app has started, see AfterRun
+	onChange    []Command // hooks run as soon as a watched file change is detected, see OnChange
 }
 
 // NewWatch creates new watch instance, watches go files recursively in current
@@ -56,18 +72,6 @@ func NewWatch() *watch {
 	}
 }
 
-// IgnoreDirectory adds directory name to directory ignore list. Ignore
-// directories without go files could reduce CPU usage.
-func (w *watch) IgnoreDirectory(dirs ...string) *watch {
-	for _, dir := range dirs {
-		if dir == "" {
-			continue
-		}
-		w.ignoreDirs = appendStringIfMissing(w.ignoreDirs, dir)
-	}
-	return w
-}
-
 // Set to true to not run executable after go build.
 func (w *watch) SetNoRun(noRun bool) *watch {
 	w.noRun = noRun
@@ -123,6 +127,32 @@ func (w *watch) WithGoBuildArgs(args ...string) *watch {
 	return w
 }
 
+// WithEnv sets extra environment variables, merged with os.Environ(), for
+// the prebuild and app run commands.
+func (w *watch) WithEnv(env map[string]string) *watch {
+	w.env = env
+	return w
+}
+
+// WithBuildEnv sets extra environment variables, merged with os.Environ(),
+// for the go build or go test command.
+func (w *watch) WithBuildEnv(env map[string]string) *watch {
+	w.buildEnv = env
+	return w
+}
+
+// WithTarget cross-compiles for goos/goarch by injecting GOOS/GOARCH into
+// the build env. When goos/goarch differ from the host, the built binary
+// generally can't run locally, so SetNoRun(true) is applied automatically.
+func (w *watch) WithTarget(goos, goarch string) *watch {
+	w.targetGOOS = goos
+	w.targetGOARCH = goarch
+	if (goos != "" && goos != runtime.GOOS) || (goarch != "" && goarch != runtime.GOARCH) {
+		w.noRun = true
+	}
+	return w
+}
+
 // WithFileExts sets file extensions or suffixes to watch. Default is .go and
 // .mod.
 func (w *watch) WithFileExts(exts ...string) *watch {
@@ -130,6 +160,14 @@ func (w *watch) WithFileExts(exts ...string) *watch {
 	return w
 }
 
+// WithWatchPaths adds extra directories to watch recursively, besides the
+// one set with InDirectory. Relative paths are resolved against that
+// directory.
+func (w *watch) WithWatchPaths(paths ...string) *watch {
+	w.extraPaths = append(w.extraPaths, paths...)
+	return w
+}
+
 // ChangeDirectory changes the working directory of commands. Default is
 // current process's current directory.
 func (w *watch) ChangeDirectory(dir string) *watch {
@@ -158,8 +196,26 @@ func (w *watch) MustDo() {
 	}
 }
 
-// Do starts the watch process.
+// logInfo logs through w.logger, prefixing the message with w.name when set
+// so output from concurrently watched projects can be told apart.
+func (w *watch) logInfo(args ...interface{}) {
+	if w.logger == nil {
+		return
+	}
+	if w.name != "" {
+		args = append([]interface{}{logger.CyanString("[" + w.name + "]")}, args...)
+	}
+	w.logger.Info(args...)
+}
+
+// Do starts the watch process. If a config file was set with
+// WithConfigFile, it fans out to doConfig instead and watches every project
+// listed in the file concurrently.
 func (w *watch) Do() error {
+	if w.configFile != "" {
+		return w.doConfig()
+	}
+
 	directory, err := filepath.Abs(w.directory)
 	if err != nil {
 		return err
@@ -193,12 +249,14 @@ func (w *watch) Do() error {
 	app := newRunner(output, w.appRunArgs...)
 	app.SetDir(w.workingDir)
 	app.SetWriter(os.Stdout)
+	app.SetEnv(w.env)
 
 	var prebuild *runner
 	if len(w.prebuild) > 0 {
 		prebuild = newRunner(w.prebuild[0], w.prebuild[1:]...)
 		prebuild.SetDir(w.workingDir)
 		prebuild.SetWriter(os.Stdout)
+		prebuild.SetEnv(w.env)
 	}
 
 	var cleanArgs []string
@@ -220,20 +278,30 @@ func (w *watch) Do() error {
 	build := newRunner(goPath, args...)
 	build.SetDir(w.workingDir)
 	build.SetWriter(os.Stdout)
+	buildEnv := map[string]string{}
+	for k, v := range w.buildEnv {
+		buildEnv[k] = v
+	}
+	if w.targetGOOS != "" {
+		buildEnv["GOOS"] = w.targetGOOS
+	}
+	if w.targetGOARCH != "" {
+		buildEnv["GOARCH"] = w.targetGOARCH
+	}
+	build.SetEnv(buildEnv)
 
 	tidy := newRunner(goPath, "mod", "tidy")
 	tidy.SetWriter(os.Stdout)
 
-	dirsToIgnore := dirsWithName(directory, w.ignoreDirs...)
-
 	wa := watcher.New()
 	wa.SetMaxEvents(1)
 	wa.Ignore(output) // prevent endless loop
 	wa.AddFilterHook(func(info os.FileInfo, fullPath string) error {
-		for _, dir := range dirsToIgnore {
-			if fullPath == dir {
+		if w.shouldIgnore(directory, fullPath) {
+			if info.IsDir() {
 				return filepath.SkipDir
 			}
+			return watcher.ErrSkip
 		}
 		if w.isWatchable(fullPath) {
 			return nil
@@ -243,30 +311,26 @@ func (w *watch) Do() error {
 	if err := wa.AddRecursive(directory); err != nil {
 		return err
 	}
+	for _, extra := range w.extraPaths {
+		if !filepath.IsAbs(extra) {
+			extra = filepath.Join(directory, extra)
+		}
+		if err := wa.AddRecursive(extra); err != nil {
+			return err
+		}
+	}
 
 	if w.logger != nil {
-		w.logger.Info("Watching", logger.CyanString(strconv.Itoa(len(wa.WatchedFiles()))), "files")
+		w.logInfo("Watching", logger.CyanString(strconv.Itoa(len(wa.WatchedFiles()))), "files")
 	}
 
-	if w.rebuildKey > 0 {
-		if w.logger != nil {
-			var action string
-			if w.isTest {
-				action = "to retest"
-			} else {
-				action = "to rebuild"
-			}
-			w.logger.Info("Enter", logger.CyanString([]byte{w.rebuildKey}), action)
-		}
-		go func() {
-			scanner := bufio.NewScanner(os.Stdin)
-			for scanner.Scan() {
-				b := scanner.Bytes()
-				if len(b) == 1 && b[0] == w.rebuildKey {
-					wa.TriggerEvent(watcher.Create, nil)
-				}
-			}
-		}()
+	// Projects of a config file don't read the stdin REPL: their Do calls
+	// run concurrently, and N goroutines scanning the same os.Stdin would
+	// race typed commands and print N help banners. The top-level watch of
+	// a config file returns from doConfig above, so no REPL runs at all in
+	// config mode.
+	if !w.subProject {
+		w.startCommandDispatcher(wa, app, clean, goPath, buildEnv)
 	}
 
 	go wa.TriggerEvent(watcher.Create, nil)
@@ -289,6 +353,12 @@ func (w *watch) Do() error {
 				}
 			}
 
+			if len(w.onChange) > 0 {
+				if err := w.runHooks("OnChange hook", w.onChange); err != nil {
+					continue
+				}
+			}
+
 			if w.logger != nil && (event.Path != "" && event.Path != "-") {
 				base, _ := filepath.Abs(".")
 				oldPath, _ := filepath.Rel(base, event.OldPath)
@@ -298,9 +368,9 @@ func (w *watch) Do() error {
 					path = event.Path
 				}
 				if event.Op == watcher.Rename || event.Op == watcher.Move {
-					w.logger.Info("File", logger.CyanString(oldPath), opts[event.Op], "to", logger.CyanString(path))
+					w.logInfo("File", logger.CyanString(oldPath), opts[event.Op], "to", logger.CyanString(path))
 				} else {
-					w.logger.Info("File", logger.CyanString(path), opts[event.Op])
+					w.logInfo("File", logger.CyanString(path), opts[event.Op])
 				}
 			}
 			if strings.HasSuffix(event.Path, ".mod") {
@@ -309,7 +379,7 @@ func (w *watch) Do() error {
 					continue
 				}
 				if w.logger != nil {
-					w.logger.Info(logger.CyanString("Running go mod tidy..."))
+					w.logInfo(logger.CyanString("Running go mod tidy..."))
 				}
 				tidy.SetDir(filepath.Dir(event.Path))
 				tidy.Run(true)
@@ -322,7 +392,7 @@ func (w *watch) Do() error {
 			app.Kill()
 			if prebuild != nil {
 				if w.logger != nil {
-					w.logger.Info(logger.CyanString("Running:"), prebuild.String())
+					w.logInfo(logger.CyanString("Running:"), prebuild.String())
 				}
 				prebuild.Run(true)
 				t := time.Now()
@@ -330,15 +400,18 @@ func (w *watch) Do() error {
 			}
 			if w.cleanFirst {
 				if w.logger != nil {
-					w.logger.Info(logger.CyanString("Cleaning..."))
+					w.logInfo(logger.CyanString("Cleaning..."))
 				}
 				clean.Run(true)
 			}
+			if err := w.runHooks("BeforeBuild hook", w.beforeBuild); err != nil {
+				continue
+			}
 			if w.logger != nil {
 				if w.isTest {
-					w.logger.Info(logger.CyanString("Testing..."))
+					w.logInfo(logger.CyanString("Testing..."))
 				} else {
-					w.logger.Info(logger.CyanString("Building..."))
+					w.logInfo(logger.CyanString("Building..."))
 				}
 			}
 			begin := time.Now()
@@ -351,10 +424,16 @@ func (w *watch) Do() error {
 					} else {
 						action = "Build"
 					}
-					w.logger.Info(logger.GreenBoldString(fmt.Sprintf("%s finished (%s)", action, spent)))
+					w.logInfo(logger.GreenBoldString(fmt.Sprintf("%s finished (%s)", action, spent)))
+				}
+				if err := w.runHooks("AfterBuild hook", w.afterBuild); err != nil {
+					continue
 				}
 				if w.isTest == false && w.noRun == false {
-					app.Run(false)
+					if err := w.runHooks("BeforeRun hook", w.beforeRun); err == nil {
+						app.Run(false)
+						w.runHooks("AfterRun hook", w.afterRun)
+					}
 				}
 			}
 		case err := <-wa.Error:
@@ -398,34 +477,3 @@ func isVersionElement(s string) bool {
 	}
 	return true
 }
-
-func appendStringIfMissing(slice []string, element string) []string {
-	for _, e := range slice {
-		if e == element {
-			return slice
-		}
-	}
-	return append(slice, element)
-}
-
-func dirsWithName(root string, names ...string) (dirs []string) {
-	if len(names) == 0 {
-		return
-	}
-	filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		if !d.IsDir() {
-			return nil
-		}
-		for _, name := range names {
-			if d.Name() == name {
-				dirs = append(dirs, path)
-				return filepath.SkipDir
-			}
-		}
-		return nil
-	})
-	return
-}